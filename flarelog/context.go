@@ -0,0 +1,38 @@
+package flarelog
+
+import (
+	"context"
+	"log/slog"
+)
+
+type loggerCtxKey struct{}
+
+// NewContext returns a copy of ctx that carries logger as its default
+// logger, retrievable with FromContext. This lets a request-scoped logger
+// (e.g. one with .With("request_id", id) already applied) travel alongside
+// ctx through a call chain.
+func NewContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerCtxKey{}, logger)
+}
+
+// FromContext returns the logger previously attached with NewContext, or
+// slog.Default() if ctx carries none.
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerCtxKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// contextAttrKey namespaces values set with WithContextValue so they don't
+// collide with unrelated context keys.
+type contextAttrKey string
+
+// WithContextValue attaches value under key on ctx. Any Handler configured
+// with a matching entry in Options.ContextKeys automatically attaches it
+// as an attribute to every record logged with that ctx, so middleware can
+// set request_id/trace_id/span_id once and have them show up on every
+// downstream log line without threading them through explicitly.
+func WithContextValue(ctx context.Context, key string, value any) context.Context {
+	return context.WithValue(ctx, contextAttrKey(key), value)
+}
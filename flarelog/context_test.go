@@ -0,0 +1,77 @@
+package flarelog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+)
+
+func TestContextRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(&buf, &Options{Format: FormatJSON}))
+
+	ctx := NewContext(context.Background(), logger)
+	if got := FromContext(ctx); got != logger {
+		t.Errorf("FromContext() = %p, want the logger passed to NewContext (%p)", got, logger)
+	}
+}
+
+func TestFromContextDefaultsWhenUnset(t *testing.T) {
+	if got := FromContext(context.Background()); got != slog.Default() {
+		t.Errorf("FromContext() = %p, want slog.Default() (%p) when ctx carries no logger", got, slog.Default())
+	}
+}
+
+func TestHandlerContextKeysAttachedAutomatically(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewHandler(&buf, &Options{
+		Format:      FormatJSON,
+		ContextKeys: []string{"request_id", "trace_id"},
+	})
+	logger := slog.New(h)
+
+	ctx := WithContextValue(context.Background(), "request_id", "req-1")
+	ctx = WithContextValue(ctx, "trace_id", "trace-1")
+	logger.InfoContext(ctx, "handled")
+
+	record := decodeJSONLine(t, &buf)
+	if record["request_id"] != "req-1" {
+		t.Errorf("record[request_id] = %v, want req-1", record["request_id"])
+	}
+	if record["trace_id"] != "trace-1" {
+		t.Errorf("record[trace_id] = %v, want trace-1", record["trace_id"])
+	}
+}
+
+func TestHandlerContextKeysIgnoresUnsetKeys(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewHandler(&buf, &Options{
+		Format:      FormatJSON,
+		ContextKeys: []string{"request_id"},
+	})
+	slog.New(h).InfoContext(context.Background(), "handled")
+
+	record := decodeJSONLine(t, &buf)
+	if _, ok := record["request_id"]; ok {
+		t.Errorf("record[request_id] = %v, want absent (context carried no value for it)", record["request_id"])
+	}
+}
+
+func TestWithContextValueDoesNotLeakAcrossKeys(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewHandler(&buf, &Options{
+		Format:      FormatJSON,
+		ContextKeys: []string{"request_id"},
+	})
+
+	// A plain string key isn't the same as contextAttrKey("other"); setting
+	// it directly on the context must not be picked up as request_id.
+	ctx := context.WithValue(context.Background(), "other", "leaked") //nolint:staticcheck
+	slog.New(h).InfoContext(ctx, "handled")
+
+	record := decodeJSONLine(t, &buf)
+	if _, ok := record["request_id"]; ok {
+		t.Errorf("record[request_id] = %v, want absent", record["request_id"])
+	}
+}
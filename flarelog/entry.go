@@ -0,0 +1,64 @@
+package flarelog
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"time"
+)
+
+// Entry is a fluent builder around a context and its logger (see
+// FromContext), letting call sites chain .With/.Caller before picking a
+// terminal level method:
+//
+//	flarelog.Log(ctx).With("user", id).Info("logged in")
+type Entry struct {
+	ctx    context.Context
+	logger *slog.Logger
+	pc     uintptr
+}
+
+// Log returns an Entry bound to ctx's logger.
+func Log(ctx context.Context) *Entry {
+	return &Entry{ctx: ctx, logger: FromContext(ctx)}
+}
+
+// Caller overrides the program counter recorded as the log call site.
+// Useful when Entry methods are invoked through a wrapper, so the
+// rendered source location points at the wrapper's caller instead of the
+// wrapper itself.
+func (e *Entry) Caller(pc uintptr) *Entry {
+	clone := *e
+	clone.pc = pc
+	return &clone
+}
+
+// With returns a copy of e whose logger has args attached to every
+// subsequent record, with the same key/value semantics as slog.Logger.With.
+func (e *Entry) With(args ...any) *Entry {
+	clone := *e
+	clone.logger = e.logger.With(args...)
+	return &clone
+}
+
+func (e *Entry) log(level slog.Level, msg string, args ...any) {
+	if !e.logger.Enabled(e.ctx, level) {
+		return
+	}
+
+	pc := e.pc
+	if pc == 0 {
+		var pcs [1]uintptr
+		runtime.Callers(3, pcs[:])
+		pc = pcs[0]
+	}
+
+	r := slog.NewRecord(time.Now(), level, msg, pc)
+	r.Add(args...)
+	_ = e.logger.Handler().Handle(e.ctx, r)
+}
+
+func (e *Entry) Debug(msg string, args ...any) { e.log(slog.LevelDebug, msg, args...) }
+func (e *Entry) Info(msg string, args ...any)  { e.log(slog.LevelInfo, msg, args...) }
+func (e *Entry) Warn(msg string, args ...any)  { e.log(slog.LevelWarn, msg, args...) }
+func (e *Entry) Error(msg string, args ...any) { e.log(slog.LevelError, msg, args...) }
@@ -0,0 +1,88 @@
+package flarelog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"runtime"
+	"testing"
+)
+
+func TestEntryLogsThroughContextLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(&buf, &Options{Format: FormatJSON}))
+	ctx := NewContext(context.Background(), logger)
+
+	Log(ctx).With("key", "value").Info("hello")
+
+	record := decodeJSONLine(t, &buf)
+	if record["msg"] != "hello" {
+		t.Errorf("record[msg] = %v, want hello", record["msg"])
+	}
+	if record["key"] != "value" {
+		t.Errorf("record[key] = %v, want value", record["key"])
+	}
+}
+
+func TestEntryWithReturnsIndependentCopy(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(&buf, &Options{Format: FormatJSON}))
+	ctx := NewContext(context.Background(), logger)
+
+	base := Log(ctx)
+	withAttr := base.With("key", "value")
+	base.Info("base")
+
+	record := decodeJSONLine(t, &buf)
+	if _, ok := record["key"]; ok {
+		t.Errorf("record[key] = %v, want absent (With must not mutate the receiver)", record["key"])
+	}
+
+	buf.Reset()
+	withAttr.Info("derived")
+	record = decodeJSONLine(t, &buf)
+	if record["key"] != "value" {
+		t.Errorf("record[key] = %v, want value", record["key"])
+	}
+}
+
+func TestEntryCallerOverridesSourceLocation(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(&buf, &Options{Format: FormatJSON, AddSource: true}))
+	ctx := NewContext(context.Background(), logger)
+
+	pc, wantFile, wantLine, ok := runtime.Caller(0)
+	if !ok {
+		t.Fatal("runtime.Caller(0) failed")
+	}
+	Log(ctx).Caller(pc).Info("from elsewhere")
+
+	record := decodeJSONLine(t, &buf)
+	src, ok := record["source"].(map[string]any)
+	if !ok {
+		t.Fatalf("record[source] = %v, want nested object", record["source"])
+	}
+	if src["file"] != wantFile {
+		t.Errorf("record[source][file] = %v, want %v", src["file"], wantFile)
+	}
+	if int(src["line"].(float64)) != wantLine {
+		t.Errorf("record[source][line] = %v, want %d (Caller's pc, not Info's own call site)", src["line"], wantLine)
+	}
+}
+
+func TestEntryLevelsRespectHandlerLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(&buf, &Options{Format: FormatJSON, Level: slog.LevelWarn}))
+	ctx := NewContext(context.Background(), logger)
+
+	Log(ctx).Debug("should not appear")
+	if buf.Len() != 0 {
+		t.Fatalf("buf = %q, want empty (Debug is below the handler's Warn level)", buf.String())
+	}
+
+	Log(ctx).Warn("should appear")
+	record := decodeJSONLine(t, &buf)
+	if record["msg"] != "should appear" {
+		t.Errorf("record[msg] = %v, want \"should appear\"", record["msg"])
+	}
+}
@@ -0,0 +1,64 @@
+package flarelog
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+// fanoutHandler dispatches every record to a fixed set of slog.Handlers,
+// e.g. a pretty-color Handler on stderr alongside a JSON Handler writing to
+// a rotating file.
+type fanoutHandler struct {
+	handlers []slog.Handler
+}
+
+// Fanout combines several slog.Handlers into one: every record handled by
+// the returned handler is forwarded to each of handlers in turn (skipping
+// any that report they aren't Enabled for the record's level). WithAttrs
+// and WithGroup are propagated to all of them, so the returned handler can
+// be used anywhere a single slog.Handler is expected.
+func Fanout(handlers ...slog.Handler) slog.Handler {
+	return &fanoutHandler{handlers: handlers}
+}
+
+func (f *fanoutHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range f.handlers {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *fanoutHandler) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, h := range f.handlers {
+		if !h.Enabled(ctx, r.Level) {
+			continue
+		}
+		// Each handler gets its own copy: a handler may add attrs to the
+		// record it is given (Handler.Handle does, for ContextKeys), and
+		// uncloned copies of a Record can share attr storage.
+		if err := h.Handle(ctx, r.Clone()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (f *fanoutHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	next := make([]slog.Handler, len(f.handlers))
+	for i, h := range f.handlers {
+		next[i] = h.WithAttrs(attrs)
+	}
+	return &fanoutHandler{handlers: next}
+}
+
+func (f *fanoutHandler) WithGroup(name string) slog.Handler {
+	next := make([]slog.Handler, len(f.handlers))
+	for i, h := range f.handlers {
+		next[i] = h.WithGroup(name)
+	}
+	return &fanoutHandler{handlers: next}
+}
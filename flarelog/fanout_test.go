@@ -0,0 +1,143 @@
+package flarelog
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+// recordingHandler is a minimal slog.Handler stub for exercising Fanout's
+// dispatch, Enabled-gating, WithAttrs/WithGroup propagation, and error-join
+// semantics in isolation.
+type recordingHandler struct {
+	enabled   bool
+	handled   int
+	err       error
+	attrs     []slog.Attr
+	groups    []string
+	lastAttrs []slog.Attr
+}
+
+func (h *recordingHandler) Enabled(ctx context.Context, level slog.Level) bool { return h.enabled }
+
+func (h *recordingHandler) Handle(ctx context.Context, r slog.Record) error {
+	h.handled++
+	r.Attrs(func(a slog.Attr) bool {
+		h.lastAttrs = append(h.lastAttrs, a)
+		return true
+	})
+	return h.err
+}
+
+func (h *recordingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.attrs = append(append([]slog.Attr(nil), h.attrs...), attrs...)
+	return &clone
+}
+
+func (h *recordingHandler) WithGroup(name string) slog.Handler {
+	clone := *h
+	clone.groups = append(append([]string(nil), h.groups...), name)
+	return &clone
+}
+
+func TestFanoutEnabledIsAnyHandlerEnabled(t *testing.T) {
+	f := Fanout(&recordingHandler{enabled: false}, &recordingHandler{enabled: true})
+	if !f.Enabled(context.Background(), slog.LevelInfo) {
+		t.Errorf("Enabled() = false, want true (at least one handler is enabled)")
+	}
+
+	f = Fanout(&recordingHandler{enabled: false}, &recordingHandler{enabled: false})
+	if f.Enabled(context.Background(), slog.LevelInfo) {
+		t.Errorf("Enabled() = true, want false (no handler is enabled)")
+	}
+}
+
+func TestFanoutHandleDispatchesToEnabledHandlersOnly(t *testing.T) {
+	disabled := &recordingHandler{enabled: false}
+	enabled := &recordingHandler{enabled: true}
+	f := Fanout(disabled, enabled)
+
+	r := slog.NewRecord(time.Now(), slog.LevelInfo, "msg", 0)
+	if err := f.Handle(context.Background(), r); err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+
+	if disabled.handled != 0 {
+		t.Errorf("disabled.handled = %d, want 0 (Handle must skip handlers that aren't Enabled)", disabled.handled)
+	}
+	if enabled.handled != 1 {
+		t.Errorf("enabled.handled = %d, want 1", enabled.handled)
+	}
+}
+
+func TestFanoutHandleJoinsErrors(t *testing.T) {
+	errA := errors.New("sink a failed")
+	errB := errors.New("sink b failed")
+	a := &recordingHandler{enabled: true, err: errA}
+	b := &recordingHandler{enabled: true, err: errB}
+	f := Fanout(a, b)
+
+	r := slog.NewRecord(time.Now(), slog.LevelInfo, "msg", 0)
+	err := f.Handle(context.Background(), r)
+	if err == nil {
+		t.Fatalf("Handle() error = nil, want joined errors")
+	}
+	if !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Errorf("Handle() error = %v, want both sink errors joined", err)
+	}
+}
+
+func TestFanoutHandleClonesRecordPerHandler(t *testing.T) {
+	a := &recordingHandler{enabled: true}
+	b := &recordingHandler{enabled: true}
+	f := Fanout(a, b)
+
+	r := slog.NewRecord(time.Now(), slog.LevelInfo, "msg", 0)
+	r.AddAttrs(slog.String("key", "value"))
+	if err := f.Handle(context.Background(), r); err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+
+	for _, h := range []*recordingHandler{a, b} {
+		if len(h.lastAttrs) != 1 || h.lastAttrs[0].Key != "key" {
+			t.Errorf("handler saw attrs = %v, want each handler to independently see the record's attrs", h.lastAttrs)
+		}
+	}
+}
+
+func TestFanoutWithAttrsPropagatesToAllHandlers(t *testing.T) {
+	a := &recordingHandler{enabled: true}
+	b := &recordingHandler{enabled: true}
+	f := Fanout(a, b).WithAttrs([]slog.Attr{slog.String("service", "api")})
+
+	fh, ok := f.(*fanoutHandler)
+	if !ok {
+		t.Fatalf("Fanout().WithAttrs() = %T, want *fanoutHandler", f)
+	}
+	for _, h := range fh.handlers {
+		rh := h.(*recordingHandler)
+		if len(rh.attrs) != 1 || rh.attrs[0].Key != "service" {
+			t.Errorf("handler attrs = %v, want [service]", rh.attrs)
+		}
+	}
+}
+
+func TestFanoutWithGroupPropagatesToAllHandlers(t *testing.T) {
+	a := &recordingHandler{enabled: true}
+	b := &recordingHandler{enabled: true}
+	f := Fanout(a, b).WithGroup("req")
+
+	fh, ok := f.(*fanoutHandler)
+	if !ok {
+		t.Fatalf("Fanout().WithGroup() = %T, want *fanoutHandler", f)
+	}
+	for _, h := range fh.handlers {
+		rh := h.(*recordingHandler)
+		if len(rh.groups) != 1 || rh.groups[0] != "req" {
+			t.Errorf("handler groups = %v, want [req]", rh.groups)
+		}
+	}
+}
@@ -0,0 +1,661 @@
+package flarelog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// timeFormat is used for FormatPretty/FormatText, where a short
+	// clock-only timestamp reads better on a terminal. FormatJSON/
+	// FormatLogfmt use time.RFC3339Nano instead, since those are meant to
+	// be machine-parsed and need the full date.
+	timeFormat = "[15:04:05.000]"
+
+	reset = "\033[0m"
+)
+
+// Color is an ANSI terminal foreground color code, as used by
+// Options.LevelColors to customize how a given level renders in
+// FormatPretty.
+type Color int
+
+const (
+	Black        Color = 30
+	Red          Color = 31
+	Green        Color = 32
+	Yellow       Color = 33
+	Blue         Color = 34
+	Magenta      Color = 35
+	Cyan         Color = 36
+	LightGray    Color = 37
+	DarkGray     Color = 90
+	LightRed     Color = 91
+	LightGreen   Color = 92
+	LightYellow  Color = 93
+	LightBlue    Color = 94
+	LightMagenta Color = 95
+	LightCyan    Color = 96
+	White        Color = 97
+)
+
+func colorize(color Color, v string) string {
+	return fmt.Sprintf("\033[%sm%s%s", strconv.Itoa(int(color)), v, reset)
+}
+
+// Format selects how a Handler encodes records.
+type Format string
+
+const (
+	// FormatPretty renders a human-readable, ANSI-colorized line (color is
+	// still gated by isTerminal/NoColor/ForceColor/NO_COLOR).
+	FormatPretty Format = "pretty"
+	// FormatText is FormatPretty without any color codes, useful for
+	// destinations that can't render ANSI but should still read like the
+	// pretty format.
+	FormatText Format = "text"
+	// FormatJSON renders each record as a single-line JSON object.
+	FormatJSON Format = "json"
+	// FormatLogfmt renders each record as `key=value` pairs.
+	FormatLogfmt Format = "logfmt"
+)
+
+// Options configures a Handler. It mirrors slog.HandlerOptions with
+// flarelog-specific additions.
+type Options struct {
+	// Level reports the minimum level to log. nil means slog.LevelInfo.
+	Level slog.Leveler
+
+	// AddSource causes the handler to compute the source code position
+	// of the log statement and add it to the output.
+	AddSource bool
+
+	// ReplaceAttr, if non-nil, is called on each attribute before it is
+	// logged, with the same semantics as slog.HandlerOptions.ReplaceAttr:
+	// it is never called for Group attrs themselves, only their contents.
+	ReplaceAttr func([]string, slog.Attr) slog.Attr
+
+	// Format selects the output encoding. The zero value auto-selects:
+	// FormatPretty when the destination is a terminal, FormatLogfmt
+	// otherwise, so piping to a file doesn't embed raw escape codes.
+	Format Format
+
+	// NoColor disables ANSI colorization outright, regardless of whether
+	// the destination is a terminal. ForceColor does the opposite, enabling
+	// colorization even when the destination isn't detected as a terminal.
+	// The NO_COLOR environment variable (https://no-color.org) is honored
+	// unless ForceColor is set. Neither has any effect outside FormatPretty.
+	NoColor    bool
+	ForceColor bool
+
+	// VModule overrides Level on a per-caller-package basis, in the style
+	// of glog's -vmodule flag: a comma-separated list of `pattern=level`
+	// clauses evaluated in order against the package of the log call
+	// site's full import path, or just its trailing segment, e.g.
+	// "net/http=warn,*=info" logs net/http at warn and above and every
+	// other package at info and above. The first matching pattern wins;
+	// "*" matches any package. Packages with no match fall back to Level.
+	// The call site is the first stack frame outside log/slog and any
+	// package named flarelog, so log calls made from inside flarelog are
+	// attributed to their caller, never to flarelog itself.
+	VModule string
+
+	// ContextKeys lists the names (e.g. "request_id", "trace_id",
+	// "span_id") that the Handler automatically pulls out of a record's
+	// context via WithContextValue and attaches as attrs, so middleware
+	// can inject them once and have them appear on every downstream log
+	// line without threading them through manually.
+	ContextKeys []string
+
+	// LevelLabels overrides the short tag FormatPretty/FormatText render
+	// for a given level, keyed by the exact slog.Level value. The built-in
+	// labels are "DBG"/"INF"/"WRN"/"ERR" for slog.LevelDebug/Info/Warn/
+	// Error; a custom level (e.g. `TRACE = slog.LevelDebug - 4`) renders as
+	// its nearest built-in tag plus a numeric delta (e.g. "DBG-4") unless
+	// given its own entry here.
+	LevelLabels map[slog.Level]string
+
+	// LevelColors overrides the color FormatPretty renders a given level
+	// in, keyed the same way as LevelLabels. Levels without an exact entry
+	// fall back to the color of their nearest built-in level.
+	LevelColors map[slog.Level]Color
+}
+
+// Handler is an slog.Handler that renders records to a single io.Writer.
+// Use Fanout to combine several Handlers (e.g. one per destination) into a
+// single slog.Handler.
+type Handler struct {
+	r           func([]string, slog.Attr) slog.Attr
+	w           io.Writer
+	level       slog.Leveler
+	format      Format
+	color       bool
+	addSource   bool
+	vmodule     []vmoduleRule
+	vcache      *sync.Map
+	contextKeys []string
+	levelLabels map[slog.Level]string
+	levelColors map[slog.Level]Color
+
+	// groups is the stack of names opened by WithGroup, outermost first;
+	// it scopes both attrs added by later WithAttrs calls and the
+	// record's own attrs.
+	groups []string
+
+	// stored holds the attrs accumulated by successive WithAttrs calls,
+	// each tagged with the groups that were open at the time it was added.
+	stored []attrGroup
+}
+
+// attrGroup is one batch of attrs bound via WithAttrs, along with the
+// group path (from WithGroup) that was open when it was added.
+type attrGroup struct {
+	groups []string
+	attrs  []slog.Attr
+}
+
+// bufPool hands out scratch buffers for assembling a single rendered line,
+// so concurrent Handle calls render independently instead of serializing
+// on one shared buffer.
+var bufPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+func (h *Handler) minLevel() slog.Level {
+	if h.level == nil {
+		return slog.LevelInfo
+	}
+	return h.level.Level()
+}
+
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	if len(h.vmodule) == 0 {
+		return level >= h.minLevel()
+	}
+
+	frame, ok := callerFrame()
+	if !ok {
+		return level >= h.minLevel()
+	}
+
+	if cached, ok := h.vcache.Load(frame.PC); ok {
+		return level >= cached.(slog.Level)
+	}
+
+	// Cache the resolved level for this PC either way, matched or not, so
+	// a call site with no VModule match doesn't re-walk the stack (via
+	// callerFrame) on every single Enabled check.
+	resolved := h.minLevel()
+	if override, matched := matchVModule(h.vmodule, packagePath(frame.Function)); matched {
+		resolved = override
+	}
+
+	h.vcache.Store(frame.PC, resolved)
+	return level >= resolved
+}
+
+func (h *Handler) clone() *Handler {
+	clone := *h
+	return &clone
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	clone := h.clone()
+	clone.stored = append(append([]attrGroup(nil), h.stored...), attrGroup{
+		groups: append([]string(nil), h.groups...),
+		attrs:  append([]slog.Attr(nil), attrs...),
+	})
+	return clone
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	clone := h.clone()
+	clone.groups = append(append([]string(nil), h.groups...), name)
+	return clone
+}
+
+// computeAttrs walks the attrs stored by prior WithAttrs calls and r's own
+// attrs directly, nesting them under whatever groups were open via
+// WithGroup and applying ReplaceAttr inline as each leaf is visited. This
+// replaces round-tripping through a second slog.Handler and a shared
+// buffer: the walk here touches no handler-wide state, so it doesn't
+// serialize concurrent Handle calls.
+func (h *Handler) computeAttrs(r slog.Record) map[string]any {
+	root := map[string]any{}
+
+	for _, batch := range h.stored {
+		addAttrsInto(navigateAttrs(root, batch.groups), batch.groups, batch.attrs, h.r)
+	}
+
+	// source, like time/level/msg, is always placed at the top level,
+	// regardless of any open WithGroup nesting (matching slog's built-in
+	// handlers).
+	if h.addSource && h.format != FormatPretty && h.format != FormatText {
+		if src, ok := sourceAttr(r.PC); ok {
+			addAttrInto(root, nil, src, h.r)
+		}
+	}
+
+	target := navigateAttrs(root, h.groups)
+	r.Attrs(func(a slog.Attr) bool {
+		addAttrInto(target, h.groups, a, h.r)
+		return true
+	})
+
+	pruneEmptyGroups(root)
+	return root
+}
+
+// pruneEmptyGroups removes nested maps left empty by an open WithGroup (or
+// a group attr whose own contents were all dropped by ReplaceAttr), so an
+// unused group doesn't surface as a stray "name":{} the way a real
+// slog.Attr group wouldn't.
+func pruneEmptyGroups(m map[string]any) {
+	for k, v := range m {
+		sub, ok := v.(map[string]any)
+		if !ok {
+			continue
+		}
+		pruneEmptyGroups(sub)
+		if len(sub) == 0 {
+			delete(m, k)
+		}
+	}
+}
+
+// navigateAttrs returns the nested map at groups under root, creating
+// intermediate maps as needed.
+func navigateAttrs(root map[string]any, groups []string) map[string]any {
+	m := root
+	for _, name := range groups {
+		next, _ := m[name].(map[string]any)
+		if next == nil {
+			next = map[string]any{}
+			m[name] = next
+		}
+		m = next
+	}
+	return m
+}
+
+func addAttrsInto(dst map[string]any, groups []string, attrs []slog.Attr, replaceAttr func([]string, slog.Attr) slog.Attr) {
+	for _, a := range attrs {
+		addAttrInto(dst, groups, a, replaceAttr)
+	}
+}
+
+// addAttrInto resolves a (possibly lazy) attr and inserts it into dst,
+// recursing into slog.KindGroup values. A group with no attrs is dropped
+// and a group with an empty key is inlined into dst, matching slog.Attr's
+// own semantics. ReplaceAttr is never called for Group attrs themselves,
+// only their contents, matching slog.HandlerOptions.ReplaceAttr.
+func addAttrInto(dst map[string]any, groups []string, a slog.Attr, replaceAttr func([]string, slog.Attr) slog.Attr) {
+	if a.Value.Kind() == slog.KindLogValuer {
+		a.Value = a.Value.Resolve()
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		group := a.Value.Group()
+		if len(group) == 0 {
+			return
+		}
+		if a.Key == "" {
+			addAttrsInto(dst, groups, group, replaceAttr)
+			return
+		}
+		sub := map[string]any{}
+		addAttrsInto(sub, append(append([]string(nil), groups...), a.Key), group, replaceAttr)
+		if len(sub) > 0 {
+			dst[a.Key] = sub
+		}
+		return
+	}
+
+	if replaceAttr != nil {
+		a = replaceAttr(groups, a)
+		if a.Key == "" {
+			return
+		}
+	}
+
+	dst[a.Key] = attrValue(a.Value)
+}
+
+// attrValue converts a resolved, non-group slog.Value to the plain Go
+// value that should be stored/rendered for it, switching on Kind() rather
+// than going through Value.Any()'s reflection path.
+func attrValue(v slog.Value) any {
+	switch v.Kind() {
+	case slog.KindTime:
+		return v.Time()
+	case slog.KindDuration:
+		return v.Duration()
+	case slog.KindBool:
+		return v.Bool()
+	case slog.KindInt64:
+		return v.Int64()
+	case slog.KindUint64:
+		return v.Uint64()
+	case slog.KindFloat64:
+		return v.Float64()
+	case slog.KindString:
+		return v.String()
+	default:
+		if err, ok := v.Any().(error); ok {
+			return err.Error()
+		}
+		return v.Any()
+	}
+}
+
+// sourceAttr builds a slog.SourceKey attr from pc the way slog's built-in
+// handlers do, for the formats (json/logfmt) that render source as a
+// regular attribute; pretty/text place it separately via sourceString.
+func sourceAttr(pc uintptr) (slog.Attr, bool) {
+	if pc == 0 {
+		return slog.Attr{}, false
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	if frame.File == "" {
+		return slog.Attr{}, false
+	}
+	return slog.Attr{
+		Key: slog.SourceKey,
+		Value: slog.GroupValue(
+			slog.String("function", frame.Function),
+			slog.String("file", frame.File),
+			slog.Int("line", frame.Line),
+		),
+	}, true
+}
+
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	for _, key := range h.contextKeys {
+		if v := ctx.Value(contextAttrKey(key)); v != nil {
+			r.AddAttrs(slog.Any(key, v))
+		}
+	}
+
+	levelAttr := slog.Attr{Key: slog.LevelKey, Value: slog.AnyValue(r.Level)}
+	if h.r != nil {
+		levelAttr = h.r([]string{}, levelAttr)
+	}
+
+	timeLayout := timeFormat
+	if h.format == FormatJSON || h.format == FormatLogfmt {
+		timeLayout = time.RFC3339Nano
+	}
+	timeAttr := slog.Attr{Key: slog.TimeKey, Value: slog.StringValue(r.Time.Format(timeLayout))}
+	if h.r != nil {
+		timeAttr = h.r([]string{}, timeAttr)
+	}
+
+	msgAttr := slog.Attr{Key: slog.MessageKey, Value: slog.StringValue(r.Message)}
+	if h.r != nil {
+		msgAttr = h.r([]string{}, msgAttr)
+	}
+
+	attrs := h.computeAttrs(r)
+
+	buf := bufPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer bufPool.Put(buf)
+
+	var err error
+	switch h.format {
+	case FormatJSON:
+		err = h.writeJSON(buf, timeAttr, levelAttr, msgAttr, attrs)
+	case FormatLogfmt:
+		writeLogfmtLine(buf, timeAttr, levelAttr, msgAttr, attrs)
+	case FormatText:
+		h.writePretty(buf, r, timeAttr, levelAttr, msgAttr, attrs, false)
+	default:
+		h.writePretty(buf, r, timeAttr, levelAttr, msgAttr, attrs, h.color)
+	}
+	if err != nil {
+		return fmt.Errorf("error rendering log line: %w", err)
+	}
+
+	buf.WriteByte('\n')
+	if _, err := h.w.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("error writing log line: %w", err)
+	}
+
+	return nil
+}
+
+func (h *Handler) writePretty(
+	out *bytes.Buffer,
+	r slog.Record,
+	timeAttr, levelAttr, msgAttr slog.Attr,
+	attrs map[string]any,
+	color bool,
+) {
+	if !timeAttr.Equal(slog.Attr{}) {
+		timestamp := timeAttr.Value.String()
+		if color {
+			timestamp = colorize(LightGray, timestamp)
+		}
+		out.WriteString(timestamp)
+		out.WriteString(" ")
+	}
+
+	if !levelAttr.Equal(slog.Attr{}) {
+		level := h.levelTag(r.Level, levelAttr) + ":"
+		if color {
+			level = colorize(h.levelColor(r.Level), level)
+		}
+		out.WriteString(level)
+		out.WriteString(" ")
+	}
+
+	if !msgAttr.Equal(slog.Attr{}) {
+		msg := msgAttr.Value.String()
+		if color {
+			msg = colorize(White, msg)
+		}
+		out.WriteString(msg)
+		out.WriteString(" ")
+	}
+
+	if h.addSource && r.PC != 0 {
+		if src, ok := sourceString(r.PC); ok {
+			if color {
+				src = colorize(DarkGray, src)
+			}
+			out.WriteString(src)
+			out.WriteString(" ")
+		}
+	}
+
+	if len(attrs) > 0 {
+		b, err := json.MarshalIndent(attrs, "", "  ")
+		if err == nil && len(b) > 0 {
+			if color {
+				out.WriteString(colorize(DarkGray, string(b)))
+			} else {
+				out.Write(b)
+			}
+		}
+	}
+}
+
+// sourceString renders pc as a short "file.go:line". writePretty places it
+// after the message and ahead of the attrs, dimmed when color is on; it is
+// not padded or right-aligned.
+func sourceString(pc uintptr) (string, bool) {
+	frames := runtime.CallersFrames([]uintptr{pc})
+	frame, _ := frames.Next()
+	if frame.File == "" {
+		return "", false
+	}
+	return fmt.Sprintf("%s:%d", filepath.Base(frame.File), frame.Line), true
+}
+
+// levelTier is a canonical level with the short tag and color it renders
+// as in FormatPretty/FormatText when Options.LevelLabels/LevelColors don't
+// override it.
+type levelTier struct {
+	level slog.Level
+	label string
+	color Color
+}
+
+// levelTiers is ordered from lowest to highest; nearestTier picks the
+// highest one at or below a given level.
+var levelTiers = []levelTier{
+	{slog.LevelDebug, "DBG", LightGray},
+	{slog.LevelInfo, "INF", Cyan},
+	{slog.LevelWarn, "WRN", Yellow},
+	{slog.LevelError, "ERR", LightRed},
+}
+
+// nearestTier returns the highest-valued tier at or below level, or the
+// lowest tier if level is below all of them (e.g. a TRACE level below
+// LevelDebug).
+func nearestTier(level slog.Level) levelTier {
+	tier := levelTiers[0]
+	for _, t := range levelTiers {
+		if level < t.level {
+			break
+		}
+		tier = t
+	}
+	return tier
+}
+
+// levelTag renders level as its short tag, plus a "+N"/"-N" delta suffix
+// when level isn't exactly the tier's canonical value, e.g. "INF+2" for
+// slog.LevelInfo+2 or "DBG-4" for a custom TRACE level below LevelDebug.
+// If levelAttr's value no longer holds the original slog.Level (i.e.
+// ReplaceAttr substituted it), that substituted value is used verbatim
+// instead, so ReplaceAttr can still override the rendered label.
+func (h *Handler) levelTag(level slog.Level, levelAttr slog.Attr) string {
+	if lvl, ok := levelAttr.Value.Any().(slog.Level); !ok || lvl != level {
+		return levelAttr.Value.String()
+	}
+
+	if label, ok := h.levelLabels[level]; ok {
+		return label
+	}
+
+	tier := nearestTier(level)
+	label := tier.label
+	if l, ok := h.levelLabels[tier.level]; ok {
+		label = l
+	}
+
+	if delta := level - tier.level; delta != 0 {
+		return fmt.Sprintf("%s%+d", label, int(delta))
+	}
+	return label
+}
+
+// levelColor returns the color level renders in, preferring an exact
+// Options.LevelColors entry, then the (possibly overridden) color of
+// level's nearest tier.
+func (h *Handler) levelColor(level slog.Level) Color {
+	if c, ok := h.levelColors[level]; ok {
+		return c
+	}
+
+	tier := nearestTier(level)
+	if c, ok := h.levelColors[tier.level]; ok {
+		return c
+	}
+	return tier.color
+}
+
+func (h *Handler) writeJSON(out *bytes.Buffer, timeAttr, levelAttr, msgAttr slog.Attr, attrs map[string]any) error {
+	record := make(map[string]any, len(attrs)+3)
+	for k, v := range attrs {
+		record[k] = v
+	}
+	// A zero Attr means ReplaceAttr dropped the field; a non-default Key
+	// means it was renamed. Either way, honor it rather than always
+	// writing under the hardcoded slog.*Key constant.
+	if !timeAttr.Equal(slog.Attr{}) {
+		record[timeAttr.Key] = timeAttr.Value.String()
+	}
+	if !levelAttr.Equal(slog.Attr{}) {
+		record[levelAttr.Key] = levelAttr.Value.String()
+	}
+	if !msgAttr.Equal(slog.Attr{}) {
+		record[msgAttr.Key] = msgAttr.Value.String()
+	}
+
+	b, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	out.Write(b)
+	return nil
+}
+
+func resolveColor(opts *Options, w io.Writer) bool {
+	if opts.ForceColor {
+		return true
+	}
+	if opts.NoColor || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return isTerminal(w)
+}
+
+// NewHandler returns a Handler that writes log lines to w. w is written to
+// directly and kept open for the lifetime of the Handler; callers that log
+// to a file should pass an already-open *os.File or a
+// NewRotatingFileWriter so the file handle isn't reopened on every record.
+//
+// To log to more than one destination (e.g. pretty output to stderr and
+// JSON to a file), construct a Handler per destination and combine them
+// with Fanout.
+func NewHandler(w io.Writer, opts *Options) *Handler {
+	if opts == nil {
+		opts = &Options{}
+	}
+	if w == nil {
+		w = os.Stdout
+	}
+
+	format := opts.Format
+	if format == "" {
+		if isTerminal(w) {
+			format = FormatPretty
+		} else {
+			format = FormatLogfmt
+		}
+	}
+
+	return &Handler{
+		w:           w,
+		level:       opts.Level,
+		r:           opts.ReplaceAttr,
+		format:      format,
+		color:       resolveColor(opts, w),
+		addSource:   opts.AddSource,
+		vmodule:     parseVModule(opts.VModule),
+		vcache:      &sync.Map{},
+		contextKeys: opts.ContextKeys,
+		levelLabels: opts.LevelLabels,
+		levelColors: opts.LevelColors,
+	}
+}
@@ -0,0 +1,288 @@
+package flarelog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func decodeJSONLine(t *testing.T, buf *bytes.Buffer) map[string]any {
+	t.Helper()
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("json.Unmarshal(%q): %v", buf.String(), err)
+	}
+	return record
+}
+
+func TestHandlerWithAttrsAndGroupNesting(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewHandler(&buf, &Options{Format: FormatJSON})
+
+	logger := slog.New(h).With("service", "api").WithGroup("req").With("id", 7)
+	logger.Info("handled")
+
+	record := decodeJSONLine(t, &buf)
+	if record["service"] != "api" {
+		t.Errorf("record[service] = %v, want \"api\" (attr added before WithGroup stays top-level)", record["service"])
+	}
+
+	req, ok := record["req"].(map[string]any)
+	if !ok {
+		t.Fatalf("record[req] = %v, want nested object", record["req"])
+	}
+	if req["id"] != float64(7) {
+		t.Errorf("record[req][id] = %v, want 7 (attr added after WithGroup nests under it)", req["id"])
+	}
+}
+
+func TestHandlerGroupFromRecordAttrs(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewHandler(&buf, &Options{Format: FormatJSON})
+
+	logger := slog.New(h)
+	logger.Info("handled", slog.Group("req", slog.Int("id", 7), slog.String("method", "GET")))
+
+	record := decodeJSONLine(t, &buf)
+	req, ok := record["req"].(map[string]any)
+	if !ok {
+		t.Fatalf("record[req] = %v, want nested object", record["req"])
+	}
+	if req["id"] != float64(7) || req["method"] != "GET" {
+		t.Errorf("record[req] = %v, want {id:7, method:GET}", req)
+	}
+}
+
+func TestHandlerEmptyGroupPruned(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewHandler(&buf, &Options{Format: FormatJSON})
+
+	logger := slog.New(h).WithGroup("unused")
+	logger.Info("handled")
+
+	record := decodeJSONLine(t, &buf)
+	if _, ok := record["unused"]; ok {
+		t.Errorf("record[unused] present, want pruned (group with no attrs)")
+	}
+}
+
+func TestHandlerReplaceAttrDropsTimeLevelMsg(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewHandler(&buf, &Options{
+		Format: FormatJSON,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			switch a.Key {
+			case slog.TimeKey, slog.LevelKey, slog.MessageKey:
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+
+	slog.New(h).Info("handled")
+
+	record := decodeJSONLine(t, &buf)
+	for _, key := range []string{slog.TimeKey, slog.LevelKey, slog.MessageKey} {
+		if _, ok := record[key]; ok {
+			t.Errorf("record[%s] = %v, want omitted (ReplaceAttr dropped it)", key, record[key])
+		}
+	}
+}
+
+func TestHandlerReplaceAttrRenamesTimeAndLevel(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewHandler(&buf, &Options{
+		Format: FormatJSON,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			switch a.Key {
+			case slog.TimeKey:
+				a.Key = "ts"
+			case slog.LevelKey:
+				a.Key = "lvl"
+			}
+			return a
+		},
+	})
+
+	slog.New(h).Info("handled")
+
+	record := decodeJSONLine(t, &buf)
+	if _, ok := record["ts"]; !ok {
+		t.Errorf("record[ts] missing, want renamed time attr")
+	}
+	if _, ok := record["lvl"]; !ok {
+		t.Errorf("record[lvl] missing, want renamed level attr")
+	}
+	if _, ok := record[slog.TimeKey]; ok {
+		t.Errorf("record[%s] present, want renamed away", slog.TimeKey)
+	}
+	if _, ok := record[slog.LevelKey]; ok {
+		t.Errorf("record[%s] present, want renamed away", slog.LevelKey)
+	}
+}
+
+func TestHandlerReplaceAttrDropsOrdinaryAttr(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewHandler(&buf, &Options{
+		Format: FormatJSON,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == "password" {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+
+	slog.New(h).Info("login", "user", "alice", "password", "hunter2")
+
+	record := decodeJSONLine(t, &buf)
+	if _, ok := record["password"]; ok {
+		t.Errorf("record[password] present, want dropped by ReplaceAttr")
+	}
+	if record["user"] != "alice" {
+		t.Errorf("record[user] = %v, want alice", record["user"])
+	}
+}
+
+func TestHandlerLogfmtDoesNotLeakDroppedFields(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewHandler(&buf, &Options{
+		Format: FormatLogfmt,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+
+	slog.New(h).Info("handled")
+
+	line := buf.String()
+	if strings.Contains(line, "time=") {
+		t.Errorf("line = %q, want no time= field (dropped by ReplaceAttr)", line)
+	}
+	if strings.Contains(line, "<nil>") {
+		t.Errorf("line = %q, want no <nil> literal from a dropped attr", line)
+	}
+}
+
+func TestHandlerEnabledRespectsLevel(t *testing.T) {
+	h := NewHandler(&bytes.Buffer{}, &Options{Level: slog.LevelWarn})
+	if h.Enabled(context.Background(), slog.LevelInfo) {
+		t.Errorf("Enabled(LevelInfo) = true, want false below configured Level")
+	}
+	if !h.Enabled(context.Background(), slog.LevelError) {
+		t.Errorf("Enabled(LevelError) = false, want true above configured Level")
+	}
+}
+
+func TestNearestTier(t *testing.T) {
+	tests := []struct {
+		level     slog.Level
+		wantLevel slog.Level
+	}{
+		{slog.LevelDebug - 4, slog.LevelDebug}, // below the lowest tier falls back to it
+		{slog.LevelDebug, slog.LevelDebug},
+		{slog.LevelInfo - 2, slog.LevelDebug},
+		{slog.LevelInfo, slog.LevelInfo},
+		{slog.LevelWarn + 2, slog.LevelWarn},
+		{slog.LevelError + 4, slog.LevelError},
+	}
+	for _, tt := range tests {
+		if got := nearestTier(tt.level); got.level != tt.wantLevel {
+			t.Errorf("nearestTier(%v) = %v, want %v", tt.level, got.level, tt.wantLevel)
+		}
+	}
+}
+
+func TestLevelTagDelta(t *testing.T) {
+	h := &Handler{}
+	tests := []struct {
+		level slog.Level
+		want  string
+	}{
+		{slog.LevelInfo, "INF"},
+		{slog.LevelInfo + 2, "INF+2"},
+		{slog.LevelDebug - 4, "DBG-4"},
+		{slog.LevelError, "ERR"},
+	}
+	for _, tt := range tests {
+		attr := slog.Attr{Key: slog.LevelKey, Value: slog.AnyValue(tt.level)}
+		if got := h.levelTag(tt.level, attr); got != tt.want {
+			t.Errorf("levelTag(%v) = %q, want %q", tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestLevelTagHonorsLevelLabelsOverride(t *testing.T) {
+	trace := slog.LevelDebug - 4
+	h := &Handler{levelLabels: map[slog.Level]string{trace: "TRC"}}
+	attr := slog.Attr{Key: slog.LevelKey, Value: slog.AnyValue(trace)}
+	if got := h.levelTag(trace, attr); got != "TRC" {
+		t.Errorf("levelTag(trace) = %q, want \"TRC\" (exact LevelLabels entry)", got)
+	}
+}
+
+func TestLevelTagHonorsReplaceAttrSubstitution(t *testing.T) {
+	h := &Handler{}
+	// A ReplaceAttr that swaps in a value other than the original
+	// slog.Level must be rendered verbatim instead of re-deriving a tag.
+	attr := slog.Attr{Key: slog.LevelKey, Value: slog.StringValue("CUSTOM")}
+	if got := h.levelTag(slog.LevelInfo, attr); got != "CUSTOM" {
+		t.Errorf("levelTag() = %q, want \"CUSTOM\" (ReplaceAttr substituted value wins)", got)
+	}
+}
+
+func TestLevelColorOverride(t *testing.T) {
+	h := &Handler{levelColors: map[slog.Level]Color{slog.LevelInfo: Magenta}}
+	if got := h.levelColor(slog.LevelInfo); got != Magenta {
+		t.Errorf("levelColor(LevelInfo) = %v, want %v (exact LevelColors entry)", got, Magenta)
+	}
+	if got := h.levelColor(slog.LevelError); got != LightRed {
+		t.Errorf("levelColor(LevelError) = %v, want %v (falls back to the tier's default color)", got, LightRed)
+	}
+}
+
+func TestHandlerAddSourceJSON(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewHandler(&buf, &Options{Format: FormatJSON, AddSource: true})
+
+	slog.New(h).Info("handled")
+
+	record := decodeJSONLine(t, &buf)
+	src, ok := record["source"].(map[string]any)
+	if !ok {
+		t.Fatalf("record[source] = %v, want nested object", record["source"])
+	}
+	if src["line"] == nil || src["file"] == nil || src["function"] == nil {
+		t.Errorf("record[source] = %v, want file/line/function populated", src)
+	}
+}
+
+func TestHandlerAddSourceLogfmt(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewHandler(&buf, &Options{Format: FormatLogfmt, AddSource: true})
+
+	slog.New(h).Info("handled")
+
+	line := buf.String()
+	if !strings.Contains(line, "source.function=") || !strings.Contains(line, "source.file=") || !strings.Contains(line, "source.line=") {
+		t.Errorf("line = %q, want source.function/file/line fields", line)
+	}
+}
+
+func TestHandlerAddSourcePretty(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewHandler(&buf, &Options{Format: FormatText, AddSource: true})
+
+	slog.New(h).Info("handled")
+
+	line := buf.String()
+	if !strings.Contains(line, "handler_test.go:") {
+		t.Errorf("line = %q, want a %q source location (pretty/text render source as \"file:line\", not a JSON object)", line, "handler_test.go:")
+	}
+}
@@ -0,0 +1,111 @@
+package flarelog
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+)
+
+// logfmtWriter is the subset of *bytes.Buffer (and *strings.Builder) that
+// logfmt rendering needs, so callers can hand in whichever buffer they
+// already have on hand.
+type logfmtWriter interface {
+	WriteString(string) (int, error)
+	WriteByte(byte) error
+}
+
+// writeLogfmtLine renders time, level and msg as the first three fields
+// followed by the flattened attrs, in logfmt's `key=value` form. A zero
+// Attr (ReplaceAttr returning slog.Attr{}) is omitted entirely rather than
+// rendered as "key=<nil>", and a renamed Key is honored instead of the
+// hardcoded "time"/"level"/"msg" labels, matching slog.HandlerOptions.
+func writeLogfmtLine(out logfmtWriter, timeAttr, levelAttr, msgAttr slog.Attr, attrs map[string]any) {
+	wrote := false
+	writeField := func(a slog.Attr) {
+		if a.Equal(slog.Attr{}) {
+			return
+		}
+		if wrote {
+			out.WriteByte(' ')
+		}
+		writeLogfmtPair(out, a.Key, a.Value.String())
+		wrote = true
+	}
+	writeField(timeAttr)
+	writeField(levelAttr)
+	writeField(msgAttr)
+
+	for _, kv := range flattenAttrs("", attrs) {
+		if wrote {
+			out.WriteByte(' ')
+		}
+		writeLogfmtPair(out, kv.key, logfmtValueString(kv.value))
+		wrote = true
+	}
+}
+
+type logfmtKV struct {
+	key   string
+	value any
+}
+
+// flattenAttrs walks nested slog.Group attrs (represented as nested
+// map[string]any) into a flat, dotted-key list so grouped attrs render as
+// `group.subkey=value` instead of an embedded object.
+func flattenAttrs(prefix string, attrs map[string]any) []logfmtKV {
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	kvs := make([]logfmtKV, 0, len(attrs))
+	for _, k := range keys {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		if group, ok := attrs[k].(map[string]any); ok {
+			kvs = append(kvs, flattenAttrs(key, group)...)
+			continue
+		}
+		kvs = append(kvs, logfmtKV{key: key, value: attrs[k]})
+	}
+	return kvs
+}
+
+func logfmtValueString(v any) string {
+	switch s := v.(type) {
+	case string:
+		return s
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(s)
+	}
+}
+
+// writeLogfmtPair writes `key=value`, quoting value when it contains
+// whitespace, a quote, or an `=` so the pair round-trips unambiguously.
+func writeLogfmtPair(out logfmtWriter, key, value string) {
+	out.WriteString(key)
+	out.WriteByte('=')
+	if needsLogfmtQuoting(value) {
+		out.WriteString(strconv.Quote(value))
+		return
+	}
+	out.WriteString(value)
+}
+
+func needsLogfmtQuoting(s string) bool {
+	if s == "" {
+		return true
+	}
+	for _, r := range s {
+		if r <= ' ' || r == '"' || r == '=' {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package flarelog
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+)
+
+func TestWriteLogfmtLine(t *testing.T) {
+	var buf bytes.Buffer
+	timeAttr := slog.Attr{Key: "time", Value: slog.StringValue("2026-07-30T00:00:00Z")}
+	levelAttr := slog.Attr{Key: "level", Value: slog.StringValue("INFO")}
+	msgAttr := slog.Attr{Key: "msg", Value: slog.StringValue("hello world")}
+	attrs := map[string]any{
+		"user": "alice",
+		"req": map[string]any{
+			"id":     7,
+			"method": "GET",
+		},
+	}
+
+	writeLogfmtLine(&buf, timeAttr, levelAttr, msgAttr, attrs)
+
+	want := `time=2026-07-30T00:00:00Z level=INFO msg="hello world" req.id=7 req.method=GET user=alice`
+	if buf.String() != want {
+		t.Errorf("writeLogfmtLine() = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestWriteLogfmtLineDroppedField(t *testing.T) {
+	var buf bytes.Buffer
+	levelAttr := slog.Attr{Key: "level", Value: slog.StringValue("INFO")}
+	msgAttr := slog.Attr{Key: "msg", Value: slog.StringValue("hello")}
+
+	writeLogfmtLine(&buf, slog.Attr{}, levelAttr, msgAttr, nil)
+
+	want := `level=INFO msg=hello`
+	if buf.String() != want {
+		t.Errorf("writeLogfmtLine() = %q, want %q (dropped time field omitted, not leading space)", buf.String(), want)
+	}
+}
+
+func TestFlattenAttrs(t *testing.T) {
+	attrs := map[string]any{
+		"b": 1,
+		"a": map[string]any{
+			"z": 2,
+			"y": 3,
+		},
+	}
+
+	kvs := flattenAttrs("", attrs)
+	want := []logfmtKV{
+		{key: "a.y", value: 3},
+		{key: "a.z", value: 2},
+		{key: "b", value: 1},
+	}
+	if len(kvs) != len(want) {
+		t.Fatalf("flattenAttrs() = %+v, want %+v", kvs, want)
+	}
+	for i, kv := range kvs {
+		if kv != want[i] {
+			t.Errorf("kvs[%d] = %+v, want %+v", i, kv, want[i])
+		}
+	}
+}
+
+func TestWriteLogfmtPairQuoting(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"alice", "k=alice"},
+		{"", `k=""`},
+		{"has space", `k="has space"`},
+		{`has"quote`, `k="has\"quote"`},
+		{"has=equals", `k="has=equals"`},
+		{"tab\there", `k="tab\there"`},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		writeLogfmtPair(&buf, "k", tt.value)
+		if buf.String() != tt.want {
+			t.Errorf("writeLogfmtPair(%q) = %q, want %q", tt.value, buf.String(), tt.want)
+		}
+	}
+}
+
+func TestLogfmtValueString(t *testing.T) {
+	tests := []struct {
+		value any
+		want  string
+	}{
+		{"alice", "alice"},
+		{nil, ""},
+		{7, "7"},
+		{true, "true"},
+	}
+	for _, tt := range tests {
+		if got := logfmtValueString(tt.value); got != tt.want {
+			t.Errorf("logfmtValueString(%v) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
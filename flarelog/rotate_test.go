@@ -0,0 +1,112 @@
+package flarelog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRotatingFileWriterRotatesOnMaxBytes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log")
+	w, err := NewRotatingFileWriter(path, 10, 0)
+	if err != nil {
+		t.Fatalf("NewRotatingFileWriter() error = %v", err)
+	}
+	defer w.Close()
+
+	if _, err := w.Write([]byte("12345")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if _, err := w.Write([]byte("12345")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	// This write pushes size past MaxBytes, so it must rotate first.
+	if _, err := w.Write([]byte("12345")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("ReadDir() = %d entries, want 2 (current log + one rotated file)", len(entries))
+	}
+}
+
+func TestRotatingFileWriterNoCollisionUnderRapidRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log")
+	w, err := NewRotatingFileWriter(path, 1, 0)
+	if err != nil {
+		t.Fatalf("NewRotatingFileWriter() error = %v", err)
+	}
+	defer w.Close()
+
+	const writes = 500
+	for i := 0; i < writes; i++ {
+		if _, err := w.Write([]byte("x")); err != nil {
+			t.Fatalf("Write() #%d error = %v", i, err)
+		}
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+
+	// Every write past the first exceeds MaxBytes=1 and forces a rotation,
+	// so each of the writes-1 rotations must land on a distinct file: if
+	// any two collide, os.Rename silently clobbers the earlier one and
+	// this count comes up short.
+	wantRotated := writes - 1
+	gotRotated := len(entries) - 1 // minus the still-open current log file
+	if gotRotated != wantRotated {
+		t.Fatalf("rotated file count = %d, want %d (collisions silently destroyed log segments)", gotRotated, wantRotated)
+	}
+}
+
+func TestRotatingFileWriterRotatesOnMaxAge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log")
+	w, err := NewRotatingFileWriter(path, 0, time.Millisecond)
+	if err != nil {
+		t.Fatalf("NewRotatingFileWriter() error = %v", err)
+	}
+	defer w.Close()
+
+	time.Sleep(5 * time.Millisecond)
+	if _, err := w.Write([]byte("x")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("ReadDir() = %d entries, want 2 (current log + one rotated file from MaxAge)", len(entries))
+	}
+}
+
+func TestRotatingFileWriterNoRotationBelowThresholds(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log")
+	w, err := NewRotatingFileWriter(path, 1<<20, time.Hour)
+	if err != nil {
+		t.Fatalf("NewRotatingFileWriter() error = %v", err)
+	}
+	defer w.Close()
+
+	for i := 0; i < 10; i++ {
+		if _, err := w.Write([]byte("x")); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("ReadDir() = %d entries, want 1 (no rotation yet)", len(entries))
+	}
+}
@@ -0,0 +1,21 @@
+package flarelog
+
+import (
+	"io"
+	"os"
+)
+
+// isTerminal reports whether w is a terminal, so callers can decide whether
+// it's safe to emit ANSI color codes. Non-*os.File writers (buffers,
+// network sinks, etc.) are never considered terminals.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
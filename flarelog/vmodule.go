@@ -0,0 +1,111 @@
+package flarelog
+
+import (
+	"log/slog"
+	"runtime"
+	"strings"
+)
+
+// vmoduleRule is one `pattern=level` clause of a VModule spec, e.g. the
+// `net/http=warn` in "flarelog=debug,net/http=warn,*=info". A pattern
+// matches a package's full import path or just its trailing segment (see
+// matchVModule), so "flarelog=debug" matches this package under any
+// module prefix.
+type vmoduleRule struct {
+	pattern string
+	level   slog.Level
+}
+
+// parseVModule parses a comma-separated `pattern=level` spec such as
+// "flarelog=debug,net/http=warn,*=info" into an ordered list of rules;
+// rules are matched in order and the first match wins. A pattern of "*"
+// matches any package. Invalid clauses are skipped rather than failing
+// NewHandler outright, so a typo in one rule doesn't disable logging.
+func parseVModule(spec string) []vmoduleRule {
+	if spec == "" {
+		return nil
+	}
+
+	var rules []vmoduleRule
+	for _, clause := range strings.Split(spec, ",") {
+		clause = strings.TrimSpace(clause)
+		if clause == "" {
+			continue
+		}
+
+		pattern, levelStr, ok := strings.Cut(clause, "=")
+		if !ok {
+			continue
+		}
+
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(strings.TrimSpace(levelStr))); err != nil {
+			continue
+		}
+
+		rules = append(rules, vmoduleRule{pattern: strings.TrimSpace(pattern), level: level})
+	}
+	return rules
+}
+
+// matchVModule returns the level override for pkg, if any rule matches.
+// A rule matches pkg's full import path (exactly, or as a "/"-bounded
+// prefix) or its trailing path segment, so a short pattern like
+// "flarelog" matches regardless of the module prefix in front of it
+// (e.g. "main/flarelog" or "github.com/marcbentoy/flarelog").
+func matchVModule(rules []vmoduleRule, pkg string) (slog.Level, bool) {
+	last := lastPathSegment(pkg)
+	for _, rule := range rules {
+		if rule.pattern == "*" || rule.pattern == pkg || rule.pattern == last || strings.HasPrefix(pkg, rule.pattern+"/") {
+			return rule.level, true
+		}
+	}
+	return 0, false
+}
+
+// lastPathSegment returns the portion of pkg after its final "/", or pkg
+// itself if it has none.
+func lastPathSegment(pkg string) string {
+	if i := strings.LastIndex(pkg, "/"); i >= 0 {
+		return pkg[i+1:]
+	}
+	return pkg
+}
+
+// callerFrame walks past the log/slog and flarelog frames on the stack to
+// find the actual call site (e.g. the package that called logger.Info),
+// so per-package vmodule rules can be evaluated against it.
+func callerFrame() (runtime.Frame, bool) {
+	var pcs [16]uintptr
+	n := runtime.Callers(3, pcs[:])
+	if n == 0 {
+		return runtime.Frame{}, false
+	}
+
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "log/slog.") && !isFlarelogFrame(frame.Function) {
+			return frame, true
+		}
+		if !more {
+			break
+		}
+	}
+	return runtime.Frame{}, false
+}
+
+func isFlarelogFrame(function string) bool {
+	return strings.Contains(function, "/flarelog.") || strings.HasPrefix(function, "flarelog.")
+}
+
+// packagePath extracts the package path from a runtime.Frame.Function
+// value, e.g. "main/flarelog.(*Handler).Enabled" -> "main/flarelog".
+func packagePath(function string) string {
+	lastSlash := strings.LastIndex(function, "/")
+	rest := function[lastSlash+1:]
+	if dot := strings.Index(rest, "."); dot >= 0 {
+		return function[:lastSlash+1+dot]
+	}
+	return function
+}
@@ -0,0 +1,109 @@
+package flarelog
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+)
+
+func TestParseVModule(t *testing.T) {
+	rules := parseVModule("flarelog=debug, net/http=warn ,*=info,bogus,nolevel=nope")
+	want := []vmoduleRule{
+		{pattern: "flarelog", level: slog.LevelDebug},
+		{pattern: "net/http", level: slog.LevelWarn},
+		{pattern: "*", level: slog.LevelInfo},
+	}
+	if len(rules) != len(want) {
+		t.Fatalf("parseVModule() = %+v, want %+v", rules, want)
+	}
+	for i, r := range rules {
+		if r != want[i] {
+			t.Errorf("rule %d = %+v, want %+v", i, r, want[i])
+		}
+	}
+}
+
+func TestParseVModuleEmpty(t *testing.T) {
+	if rules := parseVModule(""); rules != nil {
+		t.Errorf("parseVModule(\"\") = %+v, want nil", rules)
+	}
+}
+
+func TestMatchVModule(t *testing.T) {
+	rules := parseVModule("net/http=warn,flarelog=debug,*=info")
+
+	tests := []struct {
+		pkg       string
+		wantLevel slog.Level
+		wantOK    bool
+	}{
+		{"net/http", slog.LevelWarn, true},
+		{"github.com/marcbentoy/flarelog", slog.LevelDebug, true},
+		{"main/flarelog", slog.LevelDebug, true},
+		{"flarelog", slog.LevelDebug, true},
+		{"some/other/pkg", slog.LevelInfo, true},
+	}
+	for _, tt := range tests {
+		level, ok := matchVModule(rules, tt.pkg)
+		if ok != tt.wantOK || level != tt.wantLevel {
+			t.Errorf("matchVModule(%q) = (%v, %v), want (%v, %v)", tt.pkg, level, ok, tt.wantLevel, tt.wantOK)
+		}
+	}
+}
+
+func TestMatchVModuleNoFallback(t *testing.T) {
+	rules := parseVModule("net/http=warn")
+	if _, ok := matchVModule(rules, "some/other/pkg"); ok {
+		t.Errorf("matchVModule() matched a package with no rule and no * fallback")
+	}
+}
+
+func TestMatchVModuleFirstMatchWins(t *testing.T) {
+	rules := parseVModule("flarelog=debug,*=error")
+	level, ok := matchVModule(rules, "flarelog")
+	if !ok || level != slog.LevelDebug {
+		t.Errorf("matchVModule() = (%v, %v), want (%v, true)", level, ok, slog.LevelDebug)
+	}
+}
+
+// TestHandlerEnabledVModule exercises Enabled end-to-end through the "*"
+// rule, including the per-PC cache: the first call resolves the level by
+// walking the stack and caching it, and the second call (from the same
+// call site) must hit the cache and return the same result. A literal
+// package pattern isn't used here since the test binary's own call site is
+// an implementation detail of the testing package, not this one.
+func TestHandlerEnabledVModule(t *testing.T) {
+	h := NewHandler(nil, &Options{
+		Level:   slog.LevelWarn,
+		VModule: "*=debug",
+	})
+
+	for i := 0; i < 2; i++ {
+		if !h.Enabled(context.Background(), slog.LevelDebug) {
+			t.Fatalf("call %d: Enabled(LevelDebug) = false, want true (vmodule override)", i)
+		}
+	}
+}
+
+func TestHandlerEnabledVModuleFallback(t *testing.T) {
+	h := NewHandler(nil, &Options{
+		Level:   slog.LevelWarn,
+		VModule: "some/unrelated/pkg=debug",
+	})
+
+	for i := 0; i < 2; i++ {
+		if h.Enabled(context.Background(), slog.LevelInfo) {
+			t.Fatalf("call %d: Enabled(LevelInfo) = true, want false (no vmodule match, falls back to Level)", i)
+		}
+		if !h.Enabled(context.Background(), slog.LevelWarn) {
+			t.Fatalf("call %d: Enabled(LevelWarn) = false, want true (no vmodule match, falls back to Level)", i)
+		}
+	}
+}
+
+func TestHandlerEnabledNoVModule(t *testing.T) {
+	h := NewHandler(nil, &Options{Level: slog.LevelWarn})
+	if h.Enabled(context.Background(), slog.LevelInfo) {
+		t.Fatalf("Enabled(LevelInfo) = true, want false")
+	}
+}
@@ -2,17 +2,27 @@ package main
 
 import (
 	"log/slog"
+	"os"
 
 	"main/flarelog"
 )
 
 func test1() {
-	opts := &slog.HandlerOptions{
+	opts := &flarelog.Options{
 		Level:     slog.LevelDebug,
 		AddSource: true,
 	}
 
-	logger := slog.New(flarelog.NewHandler(opts))
+	fileWriter, err := flarelog.NewRotatingFileWriter("./logs.log", 10<<20, 0)
+	if err != nil {
+		panic(err)
+	}
+
+	handler := flarelog.Fanout(
+		flarelog.NewHandler(os.Stderr, opts),
+		flarelog.NewHandler(fileWriter, opts),
+	)
+	logger := slog.New(handler)
 
 	logger.Info("Info Level Log")
 	logger.Debug("This is a debug message")